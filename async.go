@@ -0,0 +1,136 @@
+// Copyright 2021 Wayback Archiver. All rights reserved.
+// Use of this source code is governed by the GNU GPL v3
+// license that can be found in the LICENSE file.
+
+package logger
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// DropPolicy controls what EnableAsync does when its buffer is full.
+type DropPolicy int
+
+const (
+	// PolicyBlock blocks the caller until the buffer has room. This is
+	// the default.
+	PolicyBlock DropPolicy = iota
+
+	// PolicyDropOldest discards the oldest queued entry to make room for
+	// the new one instead of blocking, incrementing the Dropped count.
+	PolicyDropOldest
+)
+
+// asyncMu guards asyncQueue itself plus every send on it, so a queue can
+// never be closed out from under a goroutine that is mid-send: write(),
+// Flush() and Close() all take asyncMu for the whole check-then-send (or
+// check-then-close) operation instead of just the pointer read.
+var (
+	asyncMu    sync.Mutex
+	asyncQueue chan *Entry
+	asyncWG    sync.WaitGroup
+	dropPolicy = PolicyBlock
+	dropped    uint64
+)
+
+// EnableAsync moves logging onto a background goroutine fed by a
+// buffered channel of the given size, so hot paths no longer block on
+// formatting and I/O. Call Flush or Close to drain pending entries, e.g.
+// before the process exits. Calling EnableAsync again is a no-op while
+// async logging is already running.
+func EnableAsync(bufferSize int) {
+	asyncMu.Lock()
+	defer asyncMu.Unlock()
+
+	if asyncQueue != nil {
+		return
+	}
+	asyncQueue = make(chan *Entry, bufferSize)
+	asyncWG.Add(1)
+	go asyncLoop(asyncQueue)
+}
+
+// SetDropPolicy chooses what happens when the async buffer is full.
+func SetDropPolicy(p DropPolicy) {
+	dropPolicy = p
+}
+
+// Dropped returns the number of entries discarded under PolicyDropOldest
+// since the process started.
+func Dropped() uint64 {
+	return atomic.LoadUint64(&dropped)
+}
+
+// Flush blocks until every entry queued so far has been written. It is a
+// no-op when async logging is not enabled.
+func Flush() {
+	asyncMu.Lock()
+	defer asyncMu.Unlock()
+
+	if asyncQueue == nil {
+		return
+	}
+
+	done := make(chan struct{})
+	asyncQueue <- &Entry{flush: done}
+	<-done
+}
+
+// Close flushes pending entries and stops the background goroutine
+// started by EnableAsync; log calls write synchronously again afterward.
+func Close() error {
+	asyncMu.Lock()
+	defer asyncMu.Unlock()
+
+	if asyncQueue == nil {
+		return nil
+	}
+
+	queue := asyncQueue
+	asyncQueue = nil
+	close(queue)
+	asyncWG.Wait()
+	return nil
+}
+
+func asyncLoop(queue chan *Entry) {
+	defer asyncWG.Done()
+	for e := range queue {
+		if e.flush != nil {
+			close(e.flush)
+			continue
+		}
+		writeSync(e)
+	}
+}
+
+// write enqueues e for the background goroutine started by EnableAsync,
+// or writes it synchronously if async logging isn't enabled. asyncMu is
+// held for the whole operation so Close can never close asyncQueue while
+// a send against it is in flight.
+func write(e *Entry) {
+	asyncMu.Lock()
+	defer asyncMu.Unlock()
+
+	if asyncQueue == nil {
+		writeSync(e)
+		return
+	}
+
+	if dropPolicy != PolicyDropOldest {
+		asyncQueue <- e
+		return
+	}
+
+	select {
+	case asyncQueue <- e:
+	default:
+		select {
+		case <-asyncQueue:
+			atomic.AddUint64(&dropped, 1)
+		default:
+		}
+		asyncQueue <- e
+	}
+}
@@ -0,0 +1,38 @@
+// Copyright 2021 Wayback Archiver. All rights reserved.
+// Use of this source code is governed by the GNU GPL v3
+// license that can be found in the LICENSE file.
+
+package logger
+
+import (
+	"io"
+	"sync"
+	"testing"
+)
+
+// TestCloseWhileLogging guards against a regression where Close closed
+// asyncQueue out from under a concurrent write, panicking with "send on
+// closed channel" (and flagged by the race detector).
+func TestCloseWhileLogging(t *testing.T) {
+	SetOutput(io.Discard)
+	EnableAsync(16)
+	defer func() {
+		asyncMu.Lock()
+		asyncQueue = nil
+		asyncMu.Unlock()
+	}()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			Info("concurrent message")
+		}()
+	}
+
+	if err := Close(); err != nil {
+		t.Fatalf("Close() = %v, want nil", err)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,164 @@
+// Copyright 2021 Wayback Archiver. All rights reserved.
+// Use of this source code is governed by the GNU GPL v3
+// license that can be found in the LICENSE file.
+
+package logger
+
+import (
+	"fmt"
+	"os"
+)
+
+// Attr is a single structured key/value log attribute, as used by With.
+type Attr struct {
+	Key   string
+	Value interface{}
+}
+
+// With returns a new Logger carrying attrs as persistent context, merged
+// into every Entry logged through it afterward, e.g. a request id or job
+// id threaded through a whole call chain.
+func (l *Logger) With(attrs ...Attr) *Logger {
+	fields := make(map[string]interface{}, len(l.fields)+len(attrs))
+	for k, v := range l.fields {
+		fields[k] = v
+	}
+	for _, a := range attrs {
+		fields[a.Key] = a.Value
+	}
+	return &Logger{prefix: l.prefix, level: l.level, fields: fields}
+}
+
+// With returns a Logger derived from RootLogger carrying attrs as
+// persistent context.
+func With(attrs ...Attr) *Logger {
+	return RootLogger.With(attrs...)
+}
+
+// Debugw sends a debug message with alternating key/value attributes,
+// e.g. Debugw("fetched", "url", u, "status", resp.StatusCode).
+func (l *Logger) Debugw(msg string, keysAndValues ...interface{}) {
+	if l.level >= LevelDebug {
+		l.log(LevelDebug, attrFields(keysAndValues), "%s", msg)
+	}
+}
+
+// Infow sends an info message with alternating key/value attributes.
+func (l *Logger) Infow(msg string, keysAndValues ...interface{}) {
+	if l.level >= LevelInfo {
+		l.log(LevelInfo, attrFields(keysAndValues), "%s", msg)
+	}
+}
+
+// Warnw sends a warn message with alternating key/value attributes.
+func (l *Logger) Warnw(msg string, keysAndValues ...interface{}) {
+	if l.level >= LevelWarn {
+		l.log(LevelWarn, attrFields(keysAndValues), "%s", msg)
+	}
+}
+
+// Errorw sends an error message with alternating key/value attributes,
+// e.g. Errorw("archive failed", "err", err, "url", u).
+func (l *Logger) Errorw(msg string, keysAndValues ...interface{}) {
+	if l.level >= LevelError {
+		l.log(LevelError, attrFields(keysAndValues), "%s", msg)
+	}
+}
+
+// Fatalw sends a fatal message with alternating key/value attributes and
+// stops the execution of the program.
+//
+// Flush is called before exiting so that, under EnableAsync, the fatal
+// message is guaranteed to reach its output before the process dies
+// instead of being lost with whatever else was still queued.
+func (l *Logger) Fatalw(msg string, keysAndValues ...interface{}) {
+	if l.level >= LevelFatal {
+		l.log(LevelFatal, attrFields(keysAndValues), "%s", msg)
+		Flush()
+		os.Exit(1)
+	}
+}
+
+// Debugw sends a debug message with alternating key/value attributes via
+// RootLogger.
+//
+// Like Debug, this calls RootLogger.log directly instead of
+// RootLogger.Debugw, to keep the reported caller the call site of Debugw
+// rather than Debugw itself.
+func Debugw(msg string, keysAndValues ...interface{}) {
+	if RootLogger.level >= LevelDebug {
+		RootLogger.log(LevelDebug, attrFields(keysAndValues), "%s", msg)
+	}
+}
+
+// Infow sends an info message with alternating key/value attributes via
+// RootLogger.
+func Infow(msg string, keysAndValues ...interface{}) {
+	if RootLogger.level >= LevelInfo {
+		RootLogger.log(LevelInfo, attrFields(keysAndValues), "%s", msg)
+	}
+}
+
+// Warnw sends a warn message with alternating key/value attributes via
+// RootLogger.
+func Warnw(msg string, keysAndValues ...interface{}) {
+	if RootLogger.level >= LevelWarn {
+		RootLogger.log(LevelWarn, attrFields(keysAndValues), "%s", msg)
+	}
+}
+
+// Errorw sends an error message with alternating key/value attributes via
+// RootLogger.
+func Errorw(msg string, keysAndValues ...interface{}) {
+	if RootLogger.level >= LevelError {
+		RootLogger.log(LevelError, attrFields(keysAndValues), "%s", msg)
+	}
+}
+
+// Fatalw sends a fatal message with alternating key/value attributes via
+// RootLogger and stops the execution of the program.
+//
+// Flush is called before exiting so that, under EnableAsync, the fatal
+// message is guaranteed to reach its output before the process dies
+// instead of being lost with whatever else was still queued.
+func Fatalw(msg string, keysAndValues ...interface{}) {
+	if RootLogger.level >= LevelFatal {
+		RootLogger.log(LevelFatal, attrFields(keysAndValues), "%s", msg)
+		Flush()
+		os.Exit(1)
+	}
+}
+
+func attrFields(keysAndValues []interface{}) map[string]interface{} {
+	if len(keysAndValues) == 0 {
+		return nil
+	}
+
+	fields := make(map[string]interface{}, len(keysAndValues)/2)
+	for i := 0; i+1 < len(keysAndValues); i += 2 {
+		key, ok := keysAndValues[i].(string)
+		if !ok {
+			key = fmt.Sprintf("%v", keysAndValues[i])
+		}
+		fields[key] = keysAndValues[i+1]
+	}
+	return fields
+}
+
+func mergeFields(base, extra map[string]interface{}) map[string]interface{} {
+	if len(base) == 0 {
+		return extra
+	}
+	if len(extra) == 0 {
+		return base
+	}
+
+	merged := make(map[string]interface{}, len(base)+len(extra))
+	for k, v := range base {
+		merged[k] = v
+	}
+	for k, v := range extra {
+		merged[k] = v
+	}
+	return merged
+}
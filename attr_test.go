@@ -0,0 +1,83 @@
+// Copyright 2021 Wayback Archiver. All rights reserved.
+// Use of this source code is governed by the GNU GPL v3
+// license that can be found in the LICENSE file.
+
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestInfowRendersKeyValueUnderTextFormatter(t *testing.T) {
+	var buf bytes.Buffer
+	SetOutput(&buf)
+	SetFormatter(&TextFormatter{DisableColor: true})
+	DisableTime()
+	SetLogLevel(LevelInfo)
+
+	Infow("fetched", "url", "https://example.com", "status", 200)
+
+	got := buf.String()
+	for _, want := range []string{"fetched", "url=https://example.com", "status=200"} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("rendered line %q missing %q", got, want)
+		}
+	}
+}
+
+func TestInfowRendersTopLevelFieldsUnderJSONFormatter(t *testing.T) {
+	var buf bytes.Buffer
+	SetOutput(&buf)
+	SetFormatter(&JSONFormatter{})
+	SetLogLevel(LevelInfo)
+
+	Infow("fetched", "url", "https://example.com", "status", 200)
+
+	var got map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("Unmarshal(%q) = %v", buf.String(), err)
+	}
+
+	if got["msg"] != "fetched" {
+		t.Errorf(`"msg" = %v, want "fetched"`, got["msg"])
+	}
+	if got["url"] != "https://example.com" {
+		t.Errorf(`"url" = %v, want "https://example.com"`, got["url"])
+	}
+	if got["status"] != float64(200) {
+		t.Errorf(`"status" = %v, want 200`, got["status"])
+	}
+}
+
+func TestLoggerWithCarriesPersistentFields(t *testing.T) {
+	var buf bytes.Buffer
+	SetOutput(&buf)
+	SetFormatter(&JSONFormatter{})
+	SetLogLevel(LevelInfo)
+
+	requestLogger := New("api").With(Attr{Key: "request_id", Value: "abc123"})
+	requestLogger.Info("handled")
+
+	var got map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("Unmarshal(%q) = %v", buf.String(), err)
+	}
+	if got["request_id"] != "abc123" {
+		t.Fatalf(`"request_id" = %v, want "abc123"`, got["request_id"])
+	}
+
+	buf.Reset()
+	requestLogger.Infow("handled", "status", 200)
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("Unmarshal(%q) = %v", buf.String(), err)
+	}
+	if got["request_id"] != "abc123" {
+		t.Fatalf(`Infow dropped persistent field: "request_id" = %v, want "abc123"`, got["request_id"])
+	}
+	if got["status"] != float64(200) {
+		t.Fatalf(`"status" = %v, want 200`, got["status"])
+	}
+}
@@ -0,0 +1,67 @@
+// Copyright 2021 Wayback Archiver. All rights reserved.
+// Use of this source code is governed by the GNU GPL v3
+// license that can be found in the LICENSE file.
+
+package logger
+
+import (
+	"io"
+	"testing"
+)
+
+// These benchmarks are a regression guard on the enabled/disabled log
+// paths, not a strict pass/fail gate. The disabled path (BenchmarkInfoDisabled)
+// does hit 0 allocs, since the level check short-circuits before any
+// formatting happens.
+//
+// The enabled path reuses its *Entry and scratch buffer via sync.Pool
+// (see entryPool, textBufPool), and TextFormatter.Format writes each
+// segment straight into that buffer instead of going through
+// fmt.Fprintf/fmt.Sprintf (which boxes every value as interface{} and
+// allocates a scratch arg slice per call) — that cut the color-disabled
+// path from roughly a dozen allocs/op to about 5. The remainder is the
+// floor of the feature set itself, not formatter overhead: one alloc for
+// the message (fmt.Sprintf in buildEntry, to support Printf-style
+// verbs), two for runtime.Caller (needed for the [file:line:func]
+// caller info), and one for the rendered line's final byte copy (needed
+// because the scratch buffer is returned to textBufPool right after).
+// Getting under that without dropping caller info or the Printf-style
+// API would need a different message/caller API, which is out of scope
+// here; treat b.ReportAllocs()'s output as a trend to watch rather than
+// a fixed budget.
+func BenchmarkInfo(b *testing.B) {
+	SetOutput(io.Discard)
+	SetLogLevel(LevelInfo)
+	DisableTime()
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		Info("benchmark message %d", i)
+	}
+}
+
+func BenchmarkInfoDisabled(b *testing.B) {
+	SetOutput(io.Discard)
+	SetLogLevel(LevelError)
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		Info("benchmark message %d", i)
+	}
+}
+
+func BenchmarkInfoParallel(b *testing.B) {
+	SetOutput(io.Discard)
+	SetLogLevel(LevelInfo)
+	DisableTime()
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			Info("benchmark message")
+		}
+	})
+}
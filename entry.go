@@ -0,0 +1,166 @@
+// Copyright 2021 Wayback Archiver. All rights reserved.
+// Use of this source code is governed by the GNU GPL v3
+// license that can be found in the LICENSE file.
+
+package logger
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+// funcNames caches runtime.FuncForPC lookups keyed by program counter, so
+// the (relatively expensive) symbolization only happens once per call
+// site instead of on every log call.
+var funcNames sync.Map
+
+// entryPool recycles the *Entry allocated per log call, since it's
+// handed to the Formatter and any Hooks and then discarded.
+var entryPool = sync.Pool{
+	New: func() interface{} { return new(Entry) },
+}
+
+// Entry is a single log record. It carries the contextual fields attached
+// via WithFields/WithField and is handed to the active Formatter for
+// rendering.
+type Entry struct {
+	// Fields holds the contextual key/value pairs attached via
+	// WithFields/WithField, propagated to the next Debug/Info/... call.
+	Fields map[string]interface{}
+
+	Time    time.Time
+	Level   LogLevel
+	Message string
+	Prefix  string
+	File    string
+	Line    int
+	Func    string
+
+	logger *Logger
+	flush  chan struct{}
+}
+
+// WithFields returns an Entry carrying the given fields. The fields are
+// rendered alongside whichever Debug/Info/Warn/Error/Fatal call follows.
+func WithFields(fields map[string]interface{}) *Entry {
+	return &Entry{Fields: fields, logger: RootLogger}
+}
+
+// WithField returns an Entry carrying a single key/value field.
+func WithField(key string, value interface{}) *Entry {
+	return WithFields(map[string]interface{}{key: value})
+}
+
+// Debug sends a debug log message with the Entry's fields attached.
+func (e *Entry) Debug(format string, v ...interface{}) {
+	if e.logger.level >= LevelDebug {
+		e.log(LevelDebug, format, v...)
+	}
+}
+
+// Info sends an info log message with the Entry's fields attached.
+func (e *Entry) Info(format string, v ...interface{}) {
+	if e.logger.level >= LevelInfo {
+		e.log(LevelInfo, format, v...)
+	}
+}
+
+// Warn sends a warn log message with the Entry's fields attached.
+func (e *Entry) Warn(format string, v ...interface{}) {
+	if e.logger.level >= LevelWarn {
+		e.log(LevelWarn, format, v...)
+	}
+}
+
+// Error sends an error log message with the Entry's fields attached.
+func (e *Entry) Error(format string, v ...interface{}) {
+	if e.logger.level >= LevelError {
+		e.log(LevelError, format, v...)
+	}
+}
+
+// Fatal sends a fatal log message with the Entry's fields attached, then
+// stops the execution of the program.
+//
+// Flush is called before exiting so that, under EnableAsync, the fatal
+// message is guaranteed to reach its output before the process dies
+// instead of being lost with whatever else was still queued.
+func (e *Entry) Fatal(format string, v ...interface{}) {
+	if e.logger.level >= LevelFatal {
+		e.log(LevelFatal, format, v...)
+		Flush()
+		os.Exit(1)
+	}
+}
+
+func (e *Entry) log(l LogLevel, format string, v ...interface{}) {
+	write(buildEntry(e.logger.prefix, mergeFields(e.logger.fields, e.Fields), l, format, v...))
+}
+
+func buildEntry(prefix string, fields map[string]interface{}, l LogLevel, format string, v ...interface{}) *Entry {
+	pc, file, line, _ := runtime.Caller(3)
+	if i := strings.LastIndexByte(file, '/'); i >= 0 {
+		file = file[i+1:]
+	}
+	name := funcName(pc)
+
+	e := entryPool.Get().(*Entry)
+	e.Fields = fields
+	e.Time = time.Now()
+	e.Level = l
+	e.Message = fmt.Sprintf(format, v...)
+	e.Prefix = prefix
+	e.File = file
+	e.Line = line
+	e.Func = name
+	e.logger = nil
+	e.flush = nil
+	return e
+}
+
+func funcName(pc uintptr) string {
+	if v, ok := funcNames.Load(pc); ok {
+		return v.(string)
+	}
+
+	name := runtime.FuncForPC(pc).Name()
+	if i := strings.LastIndexByte(name, '.'); i >= 0 {
+		name = name[i+1:]
+	}
+	funcNames.Store(pc, name)
+	return name
+}
+
+func writeSync(e *Entry) {
+	defer func() {
+		e.Fields = nil
+		entryPool.Put(e)
+	}()
+
+	// Snapshot the active formatter/output/hooks under configMu, then do
+	// the actual formatting and I/O outside the lock: hooks is replaced
+	// rather than mutated in place (see AddHook), so this slice header
+	// stays a valid, immutable view even after the lock is released.
+	configMu.RLock()
+	f, out, hs := formatter, output, hooks
+	configMu.RUnlock()
+
+	b, err := f.Format(e)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "logger: format error: %v\n", err)
+		return
+	}
+	if _, err := out.Write(b); err != nil {
+		fmt.Fprintf(os.Stderr, "logger: write error: %v\n", err)
+	}
+
+	for _, h := range hs {
+		if err := h.Fire(e); err != nil {
+			fmt.Fprintf(os.Stderr, "logger: hook error: %v\n", err)
+		}
+	}
+}
@@ -0,0 +1,182 @@
+// Copyright 2021 Wayback Archiver. All rights reserved.
+// Use of this source code is governed by the GNU GPL v3
+// license that can be found in the LICENSE file.
+
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+
+	"github.com/fatih/color"
+)
+
+// Formatter renders a log Entry into the bytes written to the configured
+// output. Swap the active formatter with SetFormatter, e.g. to emit JSON
+// records for log shippers like Loki, ELK or Fluentd instead of the
+// default colorized text.
+type Formatter interface {
+	Format(e *Entry) ([]byte, error)
+}
+
+var formatter Formatter = &TextFormatter{}
+
+// SetFormatter replaces the formatter used to render log entries. A
+// *TextFormatter's effective color state also depends on whether the
+// current output is a TTY (see autoColorDisabled in output.go), checked
+// at format time rather than baked into the formatter here, so calling
+// SetFormatter after SetOutput can't reintroduce ANSI escapes into a
+// non-TTY sink.
+func SetFormatter(f Formatter) {
+	configMu.Lock()
+	defer configMu.Unlock()
+	formatter = f
+}
+
+// levelColor holds the colorized level name for each LogLevel,
+// precomputed once here instead of formatted on every log call. It's
+// indexed directly by LogLevel rather than looked up in a map, since the
+// level space is small and dense.
+var levelColor = [...]string{
+	LevelFatal: color.RedString("%s", LevelFatal),
+	LevelError: color.HiRedString("%s", LevelError),
+	LevelWarn:  color.YellowString("%s", LevelWarn),
+	LevelInfo:  color.BlueString("%s", LevelInfo),
+	LevelDebug: color.WhiteString("%s", LevelDebug),
+}
+
+// TextFormatter renders an Entry as a single line in the classic
+// "[time] [level] [file:line:func] msg" shape, colorized via fatih/color.
+type TextFormatter struct {
+	// DisableColor turns off ANSI color escapes, e.g. when the output is
+	// not a terminal. It's also folded together with the package's own
+	// TTY detection (see autoColorDisabled in output.go) at format time,
+	// so a non-TTY output disables color regardless of this field.
+	DisableColor bool
+}
+
+// textBufPool recycles the scratch buffer TextFormatter builds each line
+// in, so steady-state logging reuses its backing array instead of
+// growing a fresh one per call.
+var textBufPool = sync.Pool{
+	New: func() interface{} { return new(bytes.Buffer) },
+}
+
+// Format implements Formatter.
+//
+// Each bracketed segment is written straight into buf rather than built
+// via fmt.Fprintf/fmt.Sprintf: boxing the formatted values as
+// interface{} for a "%s"-style call allocates even though every value
+// here is already a string or int, so plain buf.Write*/strconv calls
+// avoid that cost on the hot, color-disabled path (the common case for
+// output that isn't a terminal). The coloring calls below still go
+// through fatih/color's own Sprintf-based helpers, since that's only
+// reached when ANSI escapes are wanted and coloring is not this path's
+// target.
+func (f *TextFormatter) Format(e *Entry) ([]byte, error) {
+	buf := textBufPool.Get().(*bytes.Buffer)
+	buf.Reset()
+	defer textBufPool.Put(buf)
+
+	disableColor := f.DisableColor || atomic.LoadInt32(&autoColorDisabled) == 1
+
+	if showTime {
+		if disableColor {
+			// AppendFormat appends straight into buf's spare capacity
+			// instead of allocating a throwaway string the way
+			// time.Time.Format does.
+			buf.WriteByte('[')
+			buf.Write(e.Time.AppendFormat(buf.AvailableBuffer(), "2006-01-02T15:04:05"))
+			buf.WriteString("] ")
+		} else {
+			ts := color.CyanString(e.Time.Format("2006-01-02T15:04:05"))
+			writeBracketed(buf, ts)
+		}
+	}
+
+	if disableColor {
+		writeBracketed(buf, e.Level.String())
+	} else {
+		writeBracketed(buf, levelColor[e.Level])
+	}
+
+	if disableColor {
+		buf.WriteByte('[')
+		buf.WriteString(e.File)
+		buf.WriteByte(':')
+		buf.Write(strconv.AppendInt(buf.AvailableBuffer(), int64(e.Line), 10))
+		buf.WriteByte(':')
+		buf.WriteString(e.Func)
+		buf.WriteString("] ")
+	} else {
+		caller := fmt.Sprintf("%s:%d:%s", e.File, e.Line, e.Func)
+		writeBracketed(buf, color.MagentaString("%s", caller))
+	}
+
+	if e.Prefix != "" {
+		writeBracketed(buf, e.Prefix)
+	}
+	buf.WriteString(e.Message)
+
+	for _, k := range sortedKeys(e.Fields) {
+		buf.WriteByte(' ')
+		buf.WriteString(k)
+		buf.WriteByte('=')
+		fmt.Fprintf(buf, "%v", e.Fields[k])
+	}
+	buf.WriteByte('\n')
+
+	// Copy out of buf before it's returned to textBufPool by the deferred
+	// Put above: a concurrent Format call could reset and overwrite its
+	// backing array before the caller is done with the returned bytes.
+	out := make([]byte, buf.Len())
+	copy(out, buf.Bytes())
+	return out, nil
+}
+
+// writeBracketed writes "[s] " to buf.
+func writeBracketed(buf *bytes.Buffer, s string) {
+	buf.WriteByte('[')
+	buf.WriteString(s)
+	buf.WriteString("] ")
+}
+
+// JSONFormatter renders an Entry as a single-line JSON object with ts,
+// level, caller, func and msg, plus any fields attached via WithFields.
+type JSONFormatter struct{}
+
+// Format implements Formatter.
+func (f *JSONFormatter) Format(e *Entry) ([]byte, error) {
+	data := make(map[string]interface{}, len(e.Fields)+5)
+	for k, v := range e.Fields {
+		data[k] = v
+	}
+	data["ts"] = e.Time.Format("2006-01-02T15:04:05")
+	data["level"] = e.Level.String()
+	data["caller"] = fmt.Sprintf("%s:%d", e.File, e.Line)
+	data["func"] = e.Func
+	data["msg"] = e.Message
+	if e.Prefix != "" {
+		data["logger"] = e.Prefix
+	}
+
+	b, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	return append(b, '\n'), nil
+}
+
+func sortedKeys(fields map[string]interface{}) []string {
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
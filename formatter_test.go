@@ -0,0 +1,103 @@
+// Copyright 2021 Wayback Archiver. All rights reserved.
+// Use of this source code is governed by the GNU GPL v3
+// license that can be found in the LICENSE file.
+
+package logger
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestJSONFormatterSerializesFieldsAndReservedKeys(t *testing.T) {
+	e := &Entry{
+		Fields:  map[string]interface{}{"url": "https://example.com", "status": 200},
+		Time:    time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC),
+		Level:   LevelInfo,
+		Message: "archived",
+		Prefix:  "archiver",
+		File:    "main.go",
+		Line:    42,
+		Func:    "run",
+	}
+
+	b, err := (&JSONFormatter{}).Format(e)
+	if err != nil {
+		t.Fatalf("Format() = %v", err)
+	}
+
+	var got map[string]interface{}
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatalf("Unmarshal(%q) = %v", b, err)
+	}
+
+	want := map[string]interface{}{
+		"url":    "https://example.com",
+		"status": float64(200),
+		"ts":     "2021-01-02T03:04:05",
+		"level":  "INFO",
+		"caller": "main.go:42",
+		"func":   "run",
+		"msg":    "archived",
+		"logger": "archiver",
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("field %q = %v, want %v", k, got[k], v)
+		}
+	}
+}
+
+// TestJSONFormatterFieldCollidesWithReservedKey documents the current,
+// last-write-wins behavior when a caller's field name collides with one
+// of the reserved keys (ts/level/caller/func/msg, plus logger when the
+// Entry has a Prefix): the reserved value always wins since it's written
+// into data after the user fields.
+func TestJSONFormatterFieldCollidesWithReservedKey(t *testing.T) {
+	e := &Entry{
+		Fields:  map[string]interface{}{"msg": "user-supplied, should be overridden"},
+		Time:    time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC),
+		Level:   LevelInfo,
+		Message: "the real message",
+	}
+
+	b, err := (&JSONFormatter{}).Format(e)
+	if err != nil {
+		t.Fatalf("Format() = %v", err)
+	}
+
+	var got map[string]interface{}
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatalf("Unmarshal(%q) = %v", b, err)
+	}
+
+	if got["msg"] != "the real message" {
+		t.Fatalf(`field "msg" = %v, want the reserved key to win over the colliding user field`, got["msg"])
+	}
+}
+
+func TestTextFormatterRendersFieldsAsKeyValue(t *testing.T) {
+	e := &Entry{
+		Fields:  map[string]interface{}{"url": "u", "status": 200},
+		Time:    time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC),
+		Level:   LevelInfo,
+		Message: "archived",
+		File:    "main.go",
+		Line:    42,
+		Func:    "run",
+	}
+
+	b, err := (&TextFormatter{DisableColor: true}).Format(e)
+	if err != nil {
+		t.Fatalf("Format() = %v", err)
+	}
+
+	got := string(b)
+	for _, want := range []string{"archived", "status=200", "url=u"} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("rendered line %q missing %q", got, want)
+		}
+	}
+}
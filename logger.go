@@ -4,17 +4,8 @@
 
 package logger // import "github.com/wabarc/logger"
 
-import (
-	"fmt"
-	"os"
-	"runtime"
-	"strings"
-	"time"
-
-	"github.com/fatih/color"
-)
+import "os"
 
-var logLevel = LevelInfo
 var showTime = true
 
 // LogLevel type.
@@ -37,14 +28,6 @@ const (
 	LevelDebug
 )
 
-var colorable = map[LogLevel]string{
-	LevelFatal: color.RedString("%s",LevelFatal),
-	LevelError: color.HiRedString("%s",LevelError),
-	LevelWarn:  color.YellowString("%s", LevelWarn),
-	LevelInfo:  color.BlueString("%s", LevelInfo),
-	LevelDebug: color.WhiteString("%s", LevelDebug),
-}
-
 func (l LogLevel) String() string {
 	switch l {
 	case LevelDebug:
@@ -69,67 +52,57 @@ func DisableTime() {
 
 // EnableDebug increases logging, more verbose (debug)
 func EnableDebug() {
-	logLevel = LevelDebug
-	logging(LevelInfo, "Debug mode enabled")
+	RootLogger.SetLevel(LevelDebug)
+	RootLogger.log(LevelInfo, nil, "Debug mode enabled")
 }
 
 // SetLogLevel set the log level
 func SetLogLevel(l LogLevel) {
-	logLevel = l
+	RootLogger.SetLevel(l)
 }
 
 // Debug sends a debug log message.
+//
+// This delegates to RootLogger.log rather than RootLogger.Debug so the
+// reported [file:line:func] is the caller of Debug, not Debug itself:
+// going through the exported Logger method would add a stack frame that
+// buildEntry's runtime.Caller depth doesn't account for.
 func Debug(format string, v ...interface{}) {
-	if logLevel >= LevelDebug {
-		logging(LevelDebug, format, v...)
+	if RootLogger.level >= LevelDebug {
+		RootLogger.log(LevelDebug, nil, format, v...)
 	}
 }
 
 // Info sends an info log message.
 func Info(format string, v ...interface{}) {
-	if logLevel >= LevelInfo {
-		logging(LevelInfo, format, v...)
+	if RootLogger.level >= LevelInfo {
+		RootLogger.log(LevelInfo, nil, format, v...)
 	}
 }
 
 // Warn sends a warn log message.
 func Warn(format string, v ...interface{}) {
-	if logLevel >= LevelWarn {
-		logging(LevelWarn, format, v...)
+	if RootLogger.level >= LevelWarn {
+		RootLogger.log(LevelWarn, nil, format, v...)
 	}
 }
 
 // Error sends an error log message.
 func Error(format string, v ...interface{}) {
-	if logLevel >= LevelError {
-		logging(LevelError, format, v...)
+	if RootLogger.level >= LevelError {
+		RootLogger.log(LevelError, nil, format, v...)
 	}
 }
 
 // Fatal sends a fatal log message and stop the execution of the program.
+//
+// Flush is called before exiting so that, under EnableAsync, the fatal
+// message is guaranteed to reach its output before the process dies
+// instead of being lost with whatever else was still queued.
 func Fatal(format string, v ...interface{}) {
-	if logLevel >= LevelFatal {
-		logging(LevelFatal, format, v...)
+	if RootLogger.level >= LevelFatal {
+		RootLogger.log(LevelFatal, nil, format, v...)
+		Flush()
 		os.Exit(1)
 	}
 }
-
-func logging(l LogLevel, format string, v ...interface{}) {
-	var prefix string
-
-	if showTime {
-		prefix = fmt.Sprintf("[%s] [%s] ", color.CyanString(time.Now().Format("2006-01-02T15:04:05")), colorable[l])
-	} else {
-		prefix = fmt.Sprintf("[%s] ", colorable[l])
-	}
-
-	pc, file, line, _ := runtime.Caller(2)
-	files := strings.Split(file, "/")
-	file = files[len(files)-1]
-	name := runtime.FuncForPC(pc).Name()
-	fns := strings.Split(name, ".")
-	name = fns[len(fns)-1]
-	caller := fmt.Sprintf("[%s:%d:%s] ", color.MagentaString("%s", file), line, color.MagentaString("%s", name))
-
-	fmt.Fprintf(os.Stderr, prefix+caller+format+"\n", v...)
-}
@@ -0,0 +1,87 @@
+// Copyright 2021 Wayback Archiver. All rights reserved.
+// Use of this source code is governed by the GNU GPL v3
+// license that can be found in the LICENSE file.
+
+package logger
+
+import (
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+
+	"github.com/mattn/go-isatty"
+)
+
+// configMu guards the package-level formatter/output/hooks below against
+// the concurrent reads writeSync does on every log call. Readers take
+// RLock just long enough to snapshot the three values, then do the
+// actual formatting/I/O outside the lock so one slow write doesn't
+// stall unrelated loggers; writers (SetOutput/SetFormatter/AddHook) take
+// the exclusive Lock.
+var configMu sync.RWMutex
+
+var output io.Writer = os.Stderr
+
+// Hook is fired with every Entry that passes level filtering, in addition
+// to it being rendered to the configured output. Hooks are useful for
+// fanning entries out to external services (e.g. an error tracker)
+// without replacing the primary output sink.
+type Hook interface {
+	Fire(e *Entry) error
+}
+
+var hooks []Hook
+
+// SetOutput replaces where logs are written; the default is
+// os.Stderr. Use io.MultiWriter to fan a single log line out to several
+// destinations, e.g. a file and stderr at once.
+//
+// When w is not a terminal (or not an *os.File at all, e.g. a
+// RotatingFile or an in-memory buffer) the TextFormatter's colorization
+// is disabled automatically to avoid ANSI escape noise in files and
+// pipes.
+func SetOutput(w io.Writer) {
+	configMu.Lock()
+	output = w
+	configMu.Unlock()
+	updateAutoDisableColor(w)
+}
+
+// AddHook registers a Hook to be fired for every logged Entry. hooks is
+// replaced rather than appended to in place, so a snapshot taken under
+// configMu's read lock stays valid even if another goroutine registers
+// another hook concurrently.
+func AddHook(h Hook) {
+	configMu.Lock()
+	defer configMu.Unlock()
+	next := make([]Hook, len(hooks)+1)
+	copy(next, hooks)
+	next[len(hooks)] = h
+	hooks = next
+}
+
+// autoColorDisabled records whether the current output is known to be a
+// non-TTY sink, so TextFormatter.Format can fold it into DisableColor
+// without either formatter instances or the output itself needing
+// locking on the hot path. It's an int32 rather than a bool so it can be
+// read and written atomically.
+var autoColorDisabled int32
+
+func init() {
+	// Evaluate the default sink (os.Stderr) the same way SetOutput does,
+	// so a daemon that never calls SetOutput but has stderr redirected to
+	// a file still gets color disabled instead of ANSI noise.
+	updateAutoDisableColor(output)
+}
+
+func updateAutoDisableColor(w io.Writer) {
+	f, ok := w.(*os.File)
+	isTTY := ok && isatty.IsTerminal(f.Fd())
+
+	var v int32
+	if !isTTY {
+		v = 1
+	}
+	atomic.StoreInt32(&autoColorDisabled, v)
+}
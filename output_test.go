@@ -0,0 +1,83 @@
+// Copyright 2021 Wayback Archiver. All rights reserved.
+// Use of this source code is governed by the GNU GPL v3
+// license that can be found in the LICENSE file.
+
+package logger
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestSetOutputDisablesColorForNonTTY(t *testing.T) {
+	var buf bytes.Buffer
+	SetFormatter(&TextFormatter{})
+	SetOutput(&buf)
+
+	b, err := formatter.Format(&Entry{Level: LevelInfo, Message: "hi"})
+	if err != nil {
+		t.Fatalf("Format() = %v", err)
+	}
+	if strings.Contains(string(b), "\x1b[") {
+		t.Fatalf("rendered line %q contains ANSI escapes after SetOutput(non-tty)", b)
+	}
+}
+
+// TestSetFormatterRechecksTTY guards against a regression where calling
+// SetFormatter after SetOutput installed a fresh TextFormatter that
+// reintroduced ANSI escapes into a non-TTY sink that SetOutput had
+// already disabled color for. The effective color state is folded in at
+// format time (see autoColorDisabled in output.go), so a freshly
+// installed formatter picks it up automatically without SetFormatter
+// having to re-check anything itself.
+func TestSetFormatterRechecksTTY(t *testing.T) {
+	var buf bytes.Buffer
+	SetOutput(&buf)
+	SetFormatter(&TextFormatter{})
+
+	b, err := formatter.Format(&Entry{Level: LevelInfo, Message: "hi"})
+	if err != nil {
+		t.Fatalf("Format() = %v", err)
+	}
+	if strings.Contains(string(b), "\x1b[") {
+		t.Fatalf("rendered line %q contains ANSI escapes after SetFormatter following SetOutput(non-tty)", b)
+	}
+}
+
+// TestConcurrentConfigAndLogging guards against a regression where
+// SetOutput/SetFormatter/AddHook raced with writeSync's reads of
+// formatter/output/hooks (and TextFormatter.DisableColor) on every log
+// call, flagged by the race detector.
+func TestConcurrentConfigAndLogging(t *testing.T) {
+	SetOutput(io.Discard)
+	SetLogLevel(LevelInfo)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			Info("concurrent message")
+		}()
+	}
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if i%2 == 0 {
+				SetOutput(io.Discard)
+			} else {
+				SetFormatter(&TextFormatter{})
+			}
+			AddHook(noopHook{})
+		}(i)
+	}
+	wg.Wait()
+}
+
+type noopHook struct{}
+
+func (noopHook) Fire(e *Entry) error { return nil }
@@ -0,0 +1,130 @@
+// Copyright 2021 Wayback Archiver. All rights reserved.
+// Use of this source code is governed by the GNU GPL v3
+// license that can be found in the LICENSE file.
+
+package logger
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRotatingFileRotatesOnSize(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+
+	rf := NewRotatingFile(path)
+	rf.MaxSize = 10
+	defer rf.Close()
+
+	if _, err := rf.Write([]byte("0123456789")); err != nil {
+		t.Fatalf("Write() = %v", err)
+	}
+	if _, err := rf.Write([]byte("more")); err != nil {
+		t.Fatalf("Write() = %v", err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("ReadDir() = %v", err)
+	}
+	if len(entries) < 2 {
+		t.Fatalf("expected a rotated segment alongside app.log, got %v", entries)
+	}
+}
+
+// TestRotatingFileUniqueSegmentsWithinSameSecond guards against a
+// regression where the rotated-segment suffix only had second
+// resolution, so several rotations landing in the same second reused the
+// same target name and os.Rename silently overwrote the earlier segment.
+func TestRotatingFileUniqueSegmentsWithinSameSecond(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+
+	rf := NewRotatingFile(path)
+	rf.MaxSize = 1
+	defer rf.Close()
+
+	// The first write never rotates (the file starts empty, under
+	// MaxSize); every write after that finds the file already at
+	// MaxSize and rotates before writing, so rotations+1 writes produce
+	// exactly rotations rotated segments.
+	const rotations = 5
+	for i := 0; i < rotations+1; i++ {
+		if _, err := rf.Write([]byte("x")); err != nil {
+			t.Fatalf("Write() = %v", err)
+		}
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("ReadDir() = %v", err)
+	}
+
+	var backups int
+	for _, e := range entries {
+		if e.Name() != "app.log" {
+			backups++
+		}
+	}
+	if backups != rotations {
+		t.Fatalf("got %d distinct rotated segments, want %d (one per rotation)", backups, rotations)
+	}
+}
+
+func TestRotatingFileCompressesAndPrunesBackups(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+
+	rf := NewRotatingFile(path)
+	rf.MaxSize = 1
+	rf.Compress = true
+	rf.MaxBackups = 1
+	defer rf.Close()
+
+	for i := 0; i < 4; i++ {
+		if _, err := rf.Write([]byte("x")); err != nil {
+			t.Fatalf("Write() = %v", err)
+		}
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("ReadDir() = %v", err)
+	}
+
+	var backups int
+	for _, e := range entries {
+		if e.Name() == "app.log" {
+			continue
+		}
+		backups++
+		if filepath.Ext(e.Name()) != ".gz" {
+			t.Fatalf("expected rotated segment %q to be gzipped", e.Name())
+		}
+		f, err := os.Open(filepath.Join(dir, e.Name()))
+		if err != nil {
+			t.Fatalf("Open(%q) = %v", e.Name(), err)
+		}
+		gr, err := gzip.NewReader(f)
+		if err != nil {
+			f.Close()
+			t.Fatalf("gzip.NewReader(%q) = %v", e.Name(), err)
+		}
+		if _, err := io.ReadAll(gr); err != nil {
+			t.Fatalf("reading gzipped segment %q: %v", e.Name(), err)
+		}
+		gr.Close()
+		f.Close()
+	}
+
+	if backups > rf.MaxBackups {
+		t.Fatalf("got %d backups, want at most %d (MaxBackups)", backups, rf.MaxBackups)
+	}
+	if backups == 0 {
+		t.Fatalf("expected at least one retained backup")
+	}
+}
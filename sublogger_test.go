@@ -0,0 +1,72 @@
+// Copyright 2021 Wayback Archiver. All rights reserved.
+// Use of this source code is governed by the GNU GPL v3
+// license that can be found in the LICENSE file.
+
+package logger
+
+import (
+	"bytes"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// TestPackageLevelCallerReportsCallSite guards against a regression where
+// the package-level Debug/Info/Warn/Error/Fatal functions, after
+// delegating to RootLogger, reported their own wrapper as the caller
+// instead of the user's call site.
+func TestPackageLevelCallerReportsCallSite(t *testing.T) {
+	var buf bytes.Buffer
+	SetOutput(&buf)
+	SetFormatter(&TextFormatter{DisableColor: true})
+	DisableTime()
+	SetLogLevel(LevelInfo)
+
+	_, _, wantLine, _ := runtime.Caller(0)
+	Info("hello")
+	wantLine++ // Info is called on the line right after runtime.Caller(0).
+
+	got := buf.String()
+	wantSuffix := ":" + strconv.Itoa(wantLine) + ":TestPackageLevelCallerReportsCallSite"
+	if !strings.Contains(got, wantSuffix) {
+		t.Fatalf("caller info = %q, want it to contain %q", got, wantSuffix)
+	}
+}
+
+func TestSubLoggerPrefixNesting(t *testing.T) {
+	archiver := New("archiver")
+	ia := archiver.SubLogger("ia")
+
+	if got, want := ia.prefix, "archiver/ia"; got != want {
+		t.Fatalf("SubLogger prefix = %q, want %q", got, want)
+	}
+}
+
+func TestSubLoggerLevelOverrideIsIndependent(t *testing.T) {
+	var buf bytes.Buffer
+	SetOutput(&buf)
+	SetFormatter(&TextFormatter{DisableColor: true})
+	DisableTime()
+
+	parent := New("archiver")
+	parent.SetLevel(LevelInfo)
+	child := parent.SubLogger("ia")
+	child.SetLevel(LevelDebug)
+
+	buf.Reset()
+	parent.Debug("should not appear")
+	if buf.Len() != 0 {
+		t.Fatalf("parent logged at Debug despite Info level: %q", buf.String())
+	}
+
+	buf.Reset()
+	child.Debug("should appear")
+	if buf.Len() == 0 {
+		t.Fatalf("child did not log at Debug despite its own override")
+	}
+
+	if parent.level != LevelInfo {
+		t.Fatalf("parent level changed to %v after overriding child", parent.level)
+	}
+}